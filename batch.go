@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modes accepted by BatchRegionRequest.Mode.
+const (
+	BatchModeIntersect = "intersect"
+	BatchModeUnion     = "union"
+)
+
+// OriginConstraint is one origin's latency budget in a batch request.
+type OriginConstraint struct {
+	Region     string  `json:"region"`
+	MaxLatency float64 `json:"maxLatency"`
+}
+
+// BatchRegionRequest is the body of POST /regions/eligible/batch: find the
+// regions reachable within budget from every origin (intersect) or from
+// any origin (union).
+type BatchRegionRequest struct {
+	CloudProvider string             `json:"cloudProvider"`
+	Origins       []OriginConstraint `json:"origins"`
+	Mode          string             `json:"mode"`
+}
+
+type BatchRegionResponse struct {
+	CloudProvider   string   `json:"cloudProvider"`
+	Mode            string   `json:"mode"`
+	EligibleRegions []Region `json:"eligibleRegions"`
+}
+
+// UnknownOriginsError is returned by FindEligibleRegionsMulti when one or
+// more origin regions aren't in the latency matrix, listing all of them
+// (not just the first) so the caller can fix the whole request at once.
+type UnknownOriginsError struct {
+	Origins []string
+}
+
+func (e *UnknownOriginsError) Error() string {
+	return fmt.Sprintf("unknown origin regions: %s", strings.Join(e.Origins, ", "))
+}
+
+// UnknownOriginsErrorResponse is the JSON body sent back for an
+// UnknownOriginsError.
+type UnknownOriginsErrorResponse struct {
+	Error          string   `json:"error"`
+	UnknownOrigins []string `json:"unknownOrigins"`
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestAzureService(t *testing.T) *LatencyService {
+	t.Helper()
+	service, err := NewLatencyService(CLOUD_AZURE, AZURE_LATENCY_MATRIX_FILE, AZURE_REGION_MAP_FILE, parseAzureMapping)
+	if err != nil {
+		t.Fatalf("NewLatencyService() error = %v", err)
+	}
+	return service
+}
+
+func regionNames(regions []Region) map[string]float64 {
+	names := make(map[string]float64, len(regions))
+	for _, region := range regions {
+		names[region.CloudProviderRegion] = region.LatencyMs
+	}
+	return names
+}
+
+func TestFindEligibleRegionsMultiIntersect(t *testing.T) {
+	service := newTestAzureService(t)
+
+	regions, err := service.FindEligibleRegionsMulti([]OriginConstraint{
+		{Region: "eastus", MaxLatency: 100},
+		{Region: "westeurope", MaxLatency: 100},
+	}, BatchModeIntersect)
+	if err != nil {
+		t.Fatalf("FindEligibleRegionsMulti() error = %v", err)
+	}
+
+	names := regionNames(regions)
+	if _, ok := names["northeurope"]; !ok {
+		t.Errorf("expected northeurope to satisfy both origins, got %v", names)
+	}
+	if _, ok := names["southeastasia"]; ok {
+		t.Errorf("southeastasia exceeds both budgets, should not be eligible, got %v", names)
+	}
+
+	// The eligible region's latency should be the worst case (max) across origins.
+	if latency := names["westeurope"]; latency != 90 {
+		t.Errorf("westeurope worst-case latency = %v, want 90 (eastus->westeurope)", latency)
+	}
+}
+
+func TestFindEligibleRegionsMultiUnion(t *testing.T) {
+	service := newTestAzureService(t)
+
+	regions, err := service.FindEligibleRegionsMulti([]OriginConstraint{
+		{Region: "eastus", MaxLatency: 70},
+		{Region: "southeastasia", MaxLatency: 70},
+	}, BatchModeUnion)
+	if err != nil {
+		t.Fatalf("FindEligibleRegionsMulti() error = %v", err)
+	}
+
+	names := regionNames(regions)
+	if _, ok := names["westus"]; !ok {
+		t.Errorf("expected westus to be reachable from eastus within budget, got %v", names)
+	}
+	if _, ok := names["japaneast"]; !ok {
+		t.Errorf("expected japaneast to be reachable from southeastasia within budget, got %v", names)
+	}
+}
+
+func TestFindEligibleRegionsMultiUnknownOrigin(t *testing.T) {
+	service := newTestAzureService(t)
+
+	_, err := service.FindEligibleRegionsMulti([]OriginConstraint{
+		{Region: "eastus", MaxLatency: 100},
+		{Region: "nonexistent-1", MaxLatency: 100},
+		{Region: "nonexistent-2", MaxLatency: 100},
+	}, BatchModeIntersect)
+
+	var unknownErr *UnknownOriginsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("FindEligibleRegionsMulti() error = %v, want *UnknownOriginsError", err)
+	}
+	if len(unknownErr.Origins) != 2 {
+		t.Errorf("UnknownOriginsError.Origins = %v, want both unknown origins listed", unknownErr.Origins)
+	}
+}
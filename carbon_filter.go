@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/vicentinileonardo/region-filtering-server/internal/carbon"
+)
+
+// applyCarbonAwareness enriches regions with cached carbon-intensity
+// readings and applies the request's carbon filtering/sorting, if any was
+// requested. It returns the (possibly filtered and reordered) regions and
+// a non-nil carbonDataAvailable flag whenever the request asked for carbon
+// data at all. When the carbon source has no readings for any of the
+// regions, it falls back to latency-only behavior and reports
+// carbonDataAvailable=false.
+func applyCarbonAwareness(regions []Region, cache *carbon.Cache, request RegionRequest) ([]Region, *bool) {
+	if !request.WantsCarbonData() {
+		return regions, nil
+	}
+
+	available := false
+	if cache != nil {
+		for i := range regions {
+			if regions[i].ElectricityMapsRegion == "" {
+				continue
+			}
+			intensity, ok := cache.Get(regions[i].ElectricityMapsRegion)
+			if !ok {
+				continue
+			}
+			value := intensity.GCO2eqPerKWh
+			fetchedAt := intensity.FetchedAt
+			regions[i].CarbonIntensity = &value
+			regions[i].CarbonFetchedAt = &fetchedAt
+			available = true
+		}
+	}
+
+	availableCopy := available
+	if !available {
+		sortRegionsByLatency(regions)
+		return regions, &availableCopy
+	}
+
+	if request.MaxCarbonIntensity > 0 {
+		regions = filterByCarbon(regions, request.MaxCarbonIntensity)
+	}
+
+	switch request.SortBy {
+	case SortByCarbon:
+		sortRegionsByCarbon(regions)
+	case SortByPareto:
+		regions = paretoFrontier(regions)
+	default:
+		sortRegionsByLatency(regions)
+	}
+
+	return regions, &availableCopy
+}
+
+func filterByCarbon(regions []Region, maxCarbonIntensity float64) []Region {
+	var filtered []Region
+	for _, region := range regions {
+		if region.CarbonIntensity != nil && *region.CarbonIntensity <= maxCarbonIntensity {
+			filtered = append(filtered, region)
+		}
+	}
+	return filtered
+}
+
+func sortRegionsByLatency(regions []Region) {
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].LatencyMs < regions[j].LatencyMs
+	})
+}
+
+func sortRegionsByCarbon(regions []Region) {
+	sort.Slice(regions, func(i, j int) bool {
+		a, b := regions[i].CarbonIntensity, regions[j].CarbonIntensity
+		if a == nil {
+			return false
+		}
+		if b == nil {
+			return true
+		}
+		return *a < *b
+	})
+}
+
+// paretoFrontier keeps only the regions not dominated, on both latency and
+// carbon intensity, by another region in the set. Regions without a
+// carbon reading are dropped since they can't be compared on both axes.
+func paretoFrontier(regions []Region) []Region {
+	var candidates []Region
+	for _, region := range regions {
+		if region.CarbonIntensity != nil {
+			candidates = append(candidates, region)
+		}
+	}
+
+	var frontier []Region
+	for i, candidate := range candidates {
+		dominated := false
+		for j, other := range candidates {
+			if i != j && dominates(other, candidate) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			frontier = append(frontier, candidate)
+		}
+	}
+
+	sortRegionsByLatency(frontier)
+	return frontier
+}
+
+// dominates reports whether a is at least as good as b on both latency and
+// carbon intensity, and strictly better on at least one.
+func dominates(a, b Region) bool {
+	leLatency := a.LatencyMs <= b.LatencyMs
+	leCarbon := *a.CarbonIntensity <= *b.CarbonIntensity
+	ltLatency := a.LatencyMs < b.LatencyMs
+	ltCarbon := *a.CarbonIntensity < *b.CarbonIntensity
+	return leLatency && leCarbon && (ltLatency || ltCarbon)
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestParetoFrontier(t *testing.T) {
+	regions := []Region{
+		{CloudProviderRegion: "low-latency-high-carbon", LatencyMs: 10, CarbonIntensity: floatPtr(500)},
+		{CloudProviderRegion: "high-latency-low-carbon", LatencyMs: 200, CarbonIntensity: floatPtr(50)},
+		{CloudProviderRegion: "dominated", LatencyMs: 250, CarbonIntensity: floatPtr(600)},
+		{CloudProviderRegion: "no-carbon-data", LatencyMs: 5},
+	}
+
+	frontier := paretoFrontier(regions)
+
+	if len(frontier) != 2 {
+		t.Fatalf("paretoFrontier() returned %d regions, want 2: %+v", len(frontier), frontier)
+	}
+	if frontier[0].CloudProviderRegion != "low-latency-high-carbon" || frontier[1].CloudProviderRegion != "high-latency-low-carbon" {
+		t.Errorf("paretoFrontier() = %+v, want low-latency-high-carbon then high-latency-low-carbon", frontier)
+	}
+}
+
+func TestFilterByCarbon(t *testing.T) {
+	regions := []Region{
+		{CloudProviderRegion: "clean", CarbonIntensity: floatPtr(100)},
+		{CloudProviderRegion: "dirty", CarbonIntensity: floatPtr(600)},
+		{CloudProviderRegion: "unknown"},
+	}
+
+	filtered := filterByCarbon(regions, 200)
+
+	if len(filtered) != 1 || filtered[0].CloudProviderRegion != "clean" {
+		t.Errorf("filterByCarbon() = %+v, want only \"clean\"", filtered)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ComplianceGroupsFile = "data/compliance_groups.yaml"
+
+// ComplianceGroups maps a group alias (e.g. "EEA", "GDPR") to the ISO
+// alpha-2 country codes it expands to, loaded once at startup from
+// data/compliance_groups.yaml.
+type ComplianceGroups map[string][]string
+
+// loadComplianceGroups loads the group aliases file. A missing file is not
+// an error: callers simply get no aliases, and allowed/deniedCountries
+// then only accept raw ISO codes.
+func loadComplianceGroups(filename string) (ComplianceGroups, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return ComplianceGroups{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading compliance groups file: %v", err)
+	}
+
+	var groups ComplianceGroups
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("error parsing compliance groups file: %v", err)
+	}
+
+	return groups, nil
+}
+
+// Expand turns a mix of ISO alpha-2 codes and group aliases into the set of
+// ISO codes it represents, and the subset of tokens that were neither a
+// known alias nor a syntactically valid ISO alpha-2 code.
+func (g ComplianceGroups) Expand(tokens []string) (codes map[string]struct{}, invalid []string) {
+	codes = make(map[string]struct{})
+
+	for _, token := range tokens {
+		if group, exists := g[token]; exists {
+			for _, code := range group {
+				codes[strings.ToUpper(code)] = struct{}{}
+			}
+			continue
+		}
+
+		if isISOAlpha2(token) {
+			codes[strings.ToUpper(token)] = struct{}{}
+			continue
+		}
+
+		invalid = append(invalid, token)
+	}
+
+	return codes, invalid
+}
+
+func isISOAlpha2(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// InvalidCountryCodesErrorResponse is the JSON body sent back when
+// allowedCountries/deniedCountries contain tokens that are neither a known
+// compliance group nor a valid ISO alpha-2 code.
+type InvalidCountryCodesErrorResponse struct {
+	Error               string   `json:"error"`
+	InvalidCountryCodes []string `json:"invalidCountryCodes"`
+}
+
+// filterByResidency drops regions whose ISOCountryCodeA2 isn't in
+// allowed (when allowed is non-empty) or is in denied. Passing empty sets
+// for both leaves regions unchanged, matching the server's default
+// behavior when no residency constraints are supplied.
+func filterByResidency(regions []Region, allowed, denied map[string]struct{}) []Region {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return regions
+	}
+
+	var filtered []Region
+	for _, region := range regions {
+		if len(allowed) > 0 {
+			if _, ok := allowed[region.ISOCountryCodeA2]; !ok {
+				continue
+			}
+		}
+		if len(denied) > 0 {
+			if _, ok := denied[region.ISOCountryCodeA2]; ok {
+				continue
+			}
+		}
+		filtered = append(filtered, region)
+	}
+	return filtered
+}
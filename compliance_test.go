@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestComplianceGroupsExpand(t *testing.T) {
+	groups := ComplianceGroups{"EEA": {"DE", "FR"}}
+
+	codes, invalid := groups.Expand([]string{"EEA", "us", "not-a-code"})
+	if len(invalid) != 1 || invalid[0] != "not-a-code" {
+		t.Fatalf("Expand() invalid = %v, want [not-a-code]", invalid)
+	}
+	for _, want := range []string{"DE", "FR", "US"} {
+		if _, ok := codes[want]; !ok {
+			t.Errorf("Expand() codes = %v, want %s present", codes, want)
+		}
+	}
+}
+
+func TestFilterByResidencyNoConstraintsIsNoop(t *testing.T) {
+	regions := []Region{{CloudProviderRegion: "eastus", ISOCountryCodeA2: "US"}}
+
+	filtered := filterByResidency(regions, nil, nil)
+	if len(filtered) != 1 {
+		t.Fatalf("filterByResidency() with no constraints = %v, want input unchanged", filtered)
+	}
+}
+
+func TestFilterByResidencyAllowedAndDenied(t *testing.T) {
+	regions := []Region{
+		{CloudProviderRegion: "eastus", ISOCountryCodeA2: "US"},
+		{CloudProviderRegion: "northeurope", ISOCountryCodeA2: "IE"},
+		{CloudProviderRegion: "westeurope", ISOCountryCodeA2: "NL"},
+	}
+
+	allowed := map[string]struct{}{"US": {}, "IE": {}, "NL": {}}
+	denied := map[string]struct{}{"NL": {}}
+
+	filtered := filterByResidency(regions, allowed, denied)
+	if len(filtered) != 2 {
+		t.Fatalf("filterByResidency() = %v, want eastus and northeurope only", filtered)
+	}
+	for _, region := range filtered {
+		if region.ISOCountryCodeA2 == "NL" {
+			t.Errorf("filterByResidency() kept denied region %v", region)
+		}
+	}
+}
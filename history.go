@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const historyDateLayout = "2006-01-02"
+
+// loadHistory reads every data/<provider>/history/YYYY-MM-DD.csv file and
+// builds a source->target->samples time series, ordered chronologically,
+// alongside the same samples' latencies pre-sorted ascending so unwindowed
+// percentile queries (the common case) can index straight into them instead
+// of re-sorting on every request. A missing directory yields empty (nil)
+// results rather than an error, since history is optional: percentile
+// queries simply degrade to the current matrix when there's nothing to
+// compute over.
+func loadHistory(historyDir string) (history map[string]map[string][]HistorySample, sortedLatencies map[string]map[string][]float64, err error) {
+	entries, err := os.ReadDir(historyDir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading history directory: %v", err)
+	}
+
+	var filenames []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".csv" {
+			continue
+		}
+		filenames = append(filenames, entry.Name())
+	}
+	sort.Strings(filenames) // YYYY-MM-DD.csv sorts lexicographically == chronologically
+
+	history = make(map[string]map[string][]HistorySample)
+
+	for _, filename := range filenames {
+		date, err := time.Parse(historyDateLayout, strings.TrimSuffix(filename, ".csv"))
+		if err != nil {
+			log.Printf("Warning: skipping history file with unparsable date %s: %v", filename, err)
+			continue
+		}
+
+		matrix, _, err := loadLatencyMatrix(filepath.Join(historyDir, filename))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading history file %s: %v", filename, err)
+		}
+
+		for source, destinations := range matrix {
+			if history[source] == nil {
+				history[source] = make(map[string][]HistorySample)
+			}
+			for target, latency := range destinations {
+				history[source][target] = append(history[source][target], HistorySample{Date: date, LatencyMs: latency})
+			}
+		}
+	}
+
+	sortedLatencies = make(map[string]map[string][]float64, len(history))
+	for source, destinations := range history {
+		sortedLatencies[source] = make(map[string][]float64, len(destinations))
+		for target, samples := range destinations {
+			values := make([]float64, len(samples))
+			for i, sample := range samples {
+				values[i] = sample.LatencyMs
+			}
+			sort.Float64s(values)
+			sortedLatencies[source][target] = values
+		}
+	}
+
+	return history, sortedLatencies, nil
+}
+
+// percentileAt computes the p-th percentile latency (nearest-rank) over
+// samples whose date falls within [since, until] (either bound may be
+// nil). It reports ok=false when no sample falls in the window. Narrowing
+// to a window means filtering by date first, so this always sorts on the
+// fly; callers with no window should prefer percentileAtSorted against the
+// pre-sorted samples precomputed by loadHistory.
+func percentileAt(samples []HistorySample, percentile float64, since, until *time.Time) (float64, bool) {
+	var values []float64
+	for _, sample := range samples {
+		if since != nil && sample.Date.Before(*since) {
+			continue
+		}
+		if until != nil && sample.Date.After(*until) {
+			continue
+		}
+		values = append(values, sample.LatencyMs)
+	}
+
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	sort.Float64s(values)
+	return nearestRank(values, percentile), true
+}
+
+// percentileAtSorted computes the p-th percentile latency (nearest-rank)
+// over values already sorted ascending, as precomputed by loadHistory. It
+// reports ok=false when there are no values at all.
+func percentileAtSorted(sortedValues []float64, percentile float64) (float64, bool) {
+	if len(sortedValues) == 0 {
+		return 0, false
+	}
+	return nearestRank(sortedValues, percentile), true
+}
+
+// nearestRank returns the nearest-rank percentile of values, which must
+// already be sorted ascending.
+func nearestRank(values []float64, percentile float64) float64 {
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1]
+}
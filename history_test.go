@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	date, err := time.Parse(historyDateLayout, s)
+	if err != nil {
+		t.Fatalf("time.Parse(%q) error = %v", s, err)
+	}
+	return date
+}
+
+func TestPercentileAtNearestRank(t *testing.T) {
+	samples := []HistorySample{
+		{Date: mustDate(t, "2026-07-01"), LatencyMs: 100},
+		{Date: mustDate(t, "2026-07-08"), LatencyMs: 80},
+		{Date: mustDate(t, "2026-07-15"), LatencyMs: 120},
+		{Date: mustDate(t, "2026-07-22"), LatencyMs: 90},
+	}
+
+	p50, ok := percentileAt(samples, 50, nil, nil)
+	if !ok || p50 != 90 {
+		t.Errorf("percentileAt(50) = (%v, %v), want (90, true)", p50, ok)
+	}
+
+	p99, ok := percentileAt(samples, 99, nil, nil)
+	if !ok || p99 != 120 {
+		t.Errorf("percentileAt(99) = (%v, %v), want (120, true)", p99, ok)
+	}
+}
+
+func TestPercentileAtWindowed(t *testing.T) {
+	samples := []HistorySample{
+		{Date: mustDate(t, "2026-07-01"), LatencyMs: 100},
+		{Date: mustDate(t, "2026-07-15"), LatencyMs: 200},
+	}
+	since := mustDate(t, "2026-07-10")
+
+	_, ok := percentileAt(samples, 50, &since, nil)
+	if !ok {
+		t.Fatalf("percentileAt() with since filter = ok=false, want a sample after since")
+	}
+
+	after := mustDate(t, "2026-08-01")
+	_, ok = percentileAt(samples, 50, &after, nil)
+	if ok {
+		t.Errorf("percentileAt() with since after all samples = ok=true, want false")
+	}
+}
+
+func TestLoadHistoryMissingDirDegradesGracefully(t *testing.T) {
+	history, sortedHistory, err := loadHistory("data/azure/does-not-exist")
+	if err != nil {
+		t.Fatalf("loadHistory() error = %v, want nil for a missing directory", err)
+	}
+	if history != nil {
+		t.Errorf("loadHistory() history = %v, want nil for a missing directory", history)
+	}
+	if sortedHistory != nil {
+		t.Errorf("loadHistory() sortedHistory = %v, want nil for a missing directory", sortedHistory)
+	}
+}
+
+func TestPercentileAtSorted(t *testing.T) {
+	sortedValues := []float64{80, 90, 100, 120}
+
+	p50, ok := percentileAtSorted(sortedValues, 50)
+	if !ok || p50 != 90 {
+		t.Errorf("percentileAtSorted(50) = (%v, %v), want (90, true)", p50, ok)
+	}
+
+	p99, ok := percentileAtSorted(sortedValues, 99)
+	if !ok || p99 != 120 {
+		t.Errorf("percentileAtSorted(99) = (%v, %v), want (120, true)", p99, ok)
+	}
+
+	if _, ok := percentileAtSorted(nil, 50); ok {
+		t.Errorf("percentileAtSorted(nil) ok = true, want false")
+	}
+}
@@ -0,0 +1,86 @@
+package carbon
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Intensity is a cached carbon-intensity reading for a single zone.
+type Intensity struct {
+	GCO2eqPerKWh float64
+	FetchedAt    time.Time
+}
+
+// Cache polls a Client for a fixed set of zones on an interval and serves
+// the most recent reading for each zone, expiring entries older than ttl
+// so a stalled poller degrades to "no data" rather than serving stale
+// intensities forever.
+type Cache struct {
+	client Client
+	ttl    time.Duration
+
+	mu   sync.RWMutex
+	data map[string]Intensity
+}
+
+func NewCache(client Client, ttl time.Duration) *Cache {
+	return &Cache{
+		client: client,
+		ttl:    ttl,
+		data:   make(map[string]Intensity),
+	}
+}
+
+// Get returns the cached intensity for zone if it is present and not yet
+// expired.
+func (c *Cache) Get(zone string) (Intensity, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	intensity, exists := c.data[zone]
+	if !exists || time.Since(intensity.FetchedAt) > c.ttl {
+		return Intensity{}, false
+	}
+	return intensity, true
+}
+
+// Refresh fetches the current intensity for every zone and updates the
+// cache. Zones that fail to fetch keep their previous (possibly expired)
+// entry and are logged, rather than aborting the whole refresh.
+func (c *Cache) Refresh(zones []string) {
+	for _, zone := range zones {
+		intensity, err := c.client.CarbonIntensity(zone)
+		if err != nil {
+			log.Printf("carbon: failed to fetch intensity for zone %s: %v", zone, err)
+			continue
+		}
+
+		c.mu.Lock()
+		c.data[zone] = Intensity{GCO2eqPerKWh: intensity, FetchedAt: time.Now()}
+		c.mu.Unlock()
+	}
+}
+
+// StartPolling refreshes zones immediately and then every interval, until
+// the returned stop function is called.
+func (c *Cache) StartPolling(zones []string, interval time.Duration) (stop func()) {
+	c.Refresh(zones)
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				c.Refresh(zones)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
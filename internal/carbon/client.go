@@ -0,0 +1,68 @@
+// Package carbon integrates with the Electricity Maps API to provide
+// carbon-intensity data for cloud regions, so the server can filter and
+// rank regions by their current grid carbon intensity alongside latency.
+package carbon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultBaseURL = "https://api.electricitymap.org/v3/carbon-intensity/latest"
+
+// Client fetches the current carbon intensity (gCO2eq/kWh) for an
+// Electricity Maps zone.
+type Client interface {
+	CarbonIntensity(zone string) (float64, error)
+}
+
+// ElectricityMapsClient calls the real Electricity Maps API. The API key is
+// expected to be provided via the ELECTRICITY_MAPS_API_KEY environment
+// variable at startup.
+type ElectricityMapsClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewElectricityMapsClient(apiKey string) *ElectricityMapsClient {
+	return &ElectricityMapsClient{
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+type carbonIntensityResponse struct {
+	Zone            string  `json:"zone"`
+	CarbonIntensity float64 `json:"carbonIntensity"`
+}
+
+func (c *ElectricityMapsClient) CarbonIntensity(zone string) (float64, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"?zone="+zone, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error building request for zone %s: %v", zone, err)
+	}
+	req.Header.Set("auth-token", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching carbon intensity for zone %s: %v", zone, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("electricity maps returned status %d for zone %s", resp.StatusCode, zone)
+	}
+
+	var parsed carbonIntensityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return 0, fmt.Errorf("error decoding carbon intensity response for zone %s: %v", zone, err)
+	}
+
+	return parsed.CarbonIntensity, nil
+}
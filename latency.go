@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// mappingParser turns a single data row of a provider's mapping CSV into a
+// region name and its RegionMapping. Each provider's CSV has its own extra
+// columns (e.g. AWS availability zones, GCP zones), so the parsing is
+// pluggable per provider instead of baked into loadRegionMappings.
+type mappingParser func(row []string) (region string, mapping RegionMapping)
+
+func parseAzureMapping(row []string) (string, RegionMapping) {
+	return row[0], RegionMapping{
+		isoCode:               row[1],
+		electricityMapsRegion: row[2],
+		physicalLocation:      row[4],
+	}
+}
+
+func parseAWSMapping(row []string) (string, RegionMapping) {
+	return row[0], RegionMapping{
+		isoCode:               row[1],
+		electricityMapsRegion: row[2],
+		physicalLocation:      row[4],
+		availabilityZones:     row[5],
+	}
+}
+
+func parseGCPMapping(row []string) (string, RegionMapping) {
+	return row[0], RegionMapping{
+		isoCode:               row[1],
+		electricityMapsRegion: row[2],
+		physicalLocation:      row[4],
+		zones:                 row[5],
+	}
+}
+
+func loadRegionMappings(filename string, parse mappingParser) (map[string]RegionMapping, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening region mapping file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	// Skip header
+	_, err = reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %v", err)
+	}
+
+	mappings := make(map[string]RegionMapping)
+
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break // End of file
+		}
+
+		region, mapping := parse(row)
+		mappings[region] = mapping
+	}
+
+	return mappings, nil
+}
+
+// loadLatencyMatrix parses a latency matrix CSV into a source->destination
+// latency map plus the ordered list of destination regions from the header.
+func loadLatencyMatrix(latencyFile string) (map[string]map[string]float64, []string, error) {
+	file, err := os.Open(latencyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening latency file: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	// Read header to get regions
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading header: %v", err)
+	}
+
+	latencyMatrix := make(map[string]map[string]float64)
+
+	// Read data rows
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break // End of file
+		}
+
+		sourceRegion := row[0]
+		latencyMatrix[sourceRegion] = make(map[string]float64)
+
+		for i, latencyStr := range row[1:] {
+			if latencyStr == "N/A" {
+				continue
+			}
+
+			latency, err := strconv.ParseFloat(latencyStr, 64)
+			if err != nil {
+				log.Printf("Warning: could not parse latency value %s for region %s: %v",
+					latencyStr, headers[i+1], err)
+				continue
+			}
+
+			latencyMatrix[sourceRegion][headers[i+1]] = latency
+		}
+	}
+
+	return latencyMatrix, headers[1:], nil // headers[1:] skips the "Source" column
+}
+
+// LatencyService is the per-provider engine that answers latency-based
+// eligibility queries. A LatencyService satisfies the Provider interface,
+// so each cloud provider is just a LatencyService configured with its own
+// name and mapping parser.
+//
+// latencyMatrix, regions and regionMappings are reloaded in place (see
+// Reload), so every access to them goes through mu to avoid torn reads
+// while a reload is in flight.
+type LatencyService struct {
+	name         string
+	latencyFile  string
+	mappingFile  string
+	historyDir   string
+	parseMapping mappingParser
+
+	mu             sync.RWMutex
+	latencyMatrix  map[string]map[string]float64
+	regions        []string
+	regionMappings map[string]RegionMapping
+	history        map[string]map[string][]HistorySample
+	sortedHistory  map[string]map[string][]float64
+}
+
+func NewLatencyService(name, latencyFile, mappingFile string, parse mappingParser) (*LatencyService, error) {
+	service := &LatencyService{
+		name:         name,
+		latencyFile:  latencyFile,
+		mappingFile:  mappingFile,
+		historyDir:   filepath.Join(filepath.Dir(latencyFile), "history"),
+		parseMapping: parse,
+	}
+
+	if err := service.LoadMappings(mappingFile); err != nil {
+		return nil, fmt.Errorf("error loading region mappings: %v", err)
+	}
+
+	if err := service.LoadMatrix(latencyFile); err != nil {
+		return nil, fmt.Errorf("error loading latency matrix: %v", err)
+	}
+
+	if err := service.LoadHistory(service.historyDir); err != nil {
+		return nil, fmt.Errorf("error loading latency history: %v", err)
+	}
+
+	return service, nil
+}
+
+func (s *LatencyService) Name() string {
+	return s.name
+}
+
+func (s *LatencyService) ElectricityMapsZones() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	zones := make([]string, 0, len(s.regionMappings))
+	for _, mapping := range s.regionMappings {
+		if mapping.electricityMapsRegion == "" {
+			continue
+		}
+		if _, exists := seen[mapping.electricityMapsRegion]; exists {
+			continue
+		}
+		seen[mapping.electricityMapsRegion] = struct{}{}
+		zones = append(zones, mapping.electricityMapsRegion)
+	}
+	return zones
+}
+
+func (s *LatencyService) LoadMappings(mappingFile string) error {
+	regionMappings, err := loadRegionMappings(mappingFile, s.parseMapping)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.mappingFile = mappingFile
+	s.regionMappings = regionMappings
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *LatencyService) LoadMatrix(latencyFile string) error {
+	latencyMatrix, regions, err := loadLatencyMatrix(latencyFile)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.latencyFile = latencyFile
+	s.latencyMatrix = latencyMatrix
+	s.regions = regions
+	s.mu.Unlock()
+	return nil
+}
+
+// LoadHistory (re)loads every dated matrix under historyDir (files named
+// YYYY-MM-DD.csv) into the per-(source,target) time series used for
+// percentile eligibility and the /regions/history endpoint. A missing
+// history directory is not an error: the service simply has no history,
+// and percentile queries degrade to the current matrix.
+func (s *LatencyService) LoadHistory(historyDir string) error {
+	history, sortedHistory, err := loadHistory(historyDir)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.historyDir = historyDir
+	s.history = history
+	s.sortedHistory = sortedHistory
+	s.mu.Unlock()
+	return nil
+}
+
+// Reload re-parses the matrix and mapping files this service was last
+// loaded from and swaps both in under a single write lock, so a concurrent
+// reader never observes a new matrix paired with stale mappings (or
+// vice versa).
+func (s *LatencyService) Reload() error {
+	s.mu.RLock()
+	latencyFile, mappingFile := s.latencyFile, s.mappingFile
+	s.mu.RUnlock()
+
+	latencyMatrix, regions, err := loadLatencyMatrix(latencyFile)
+	if err != nil {
+		return fmt.Errorf("error reloading latency matrix: %v", err)
+	}
+
+	regionMappings, err := loadRegionMappings(mappingFile, s.parseMapping)
+	if err != nil {
+		return fmt.Errorf("error reloading region mappings: %v", err)
+	}
+
+	history, sortedHistory, err := loadHistory(s.historyDir)
+	if err != nil {
+		return fmt.Errorf("error reloading latency history: %v", err)
+	}
+
+	s.mu.Lock()
+	s.latencyMatrix = latencyMatrix
+	s.regions = regions
+	s.regionMappings = regionMappings
+	s.history = history
+	s.sortedHistory = sortedHistory
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *LatencyService) FindEligibleRegions(cloudProviderOriginRegion string, maxLatency float64) ([]Region, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	latencies, exists := s.latencyMatrix[cloudProviderOriginRegion]
+	if !exists {
+		return nil, fmt.Errorf("cloudProviderOriginRegion %s not found", cloudProviderOriginRegion)
+	}
+
+	var eligibleRegions []Region
+	for region, latency := range latencies {
+		if latency <= maxLatency {
+			eligibleRegions = append(eligibleRegions, s.toRegionLocked(region, latency))
+		}
+	}
+
+	// adding the origin region to the list of eligible regions if it is not already there
+	// this is to ensure that the origin region is always included in the response
+	// as it could happen that in the latency matrix it has a latency of N/A
+	if _, exists := latencies[cloudProviderOriginRegion]; !exists {
+		eligibleRegions = append(eligibleRegions, s.toRegionLocked(cloudProviderOriginRegion, 0))
+	}
+
+	return eligibleRegions, nil
+}
+
+// FindEligibleRegionsMulti finds regions satisfying every origin's latency
+// budget (mode == BatchModeIntersect) or any origin's (mode ==
+// BatchModeUnion). In intersect mode each eligible region's LatencyMs is
+// the worst-case (max) latency across all origins; in union mode it's the
+// best (min) latency among the origins that qualified it.
+func (s *LatencyService) FindEligibleRegionsMulti(origins []OriginConstraint, mode string) ([]Region, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var unknownOrigins []string
+	for _, origin := range origins {
+		if _, exists := s.latencyMatrix[origin.Region]; !exists {
+			unknownOrigins = append(unknownOrigins, origin.Region)
+		}
+	}
+	if len(unknownOrigins) > 0 {
+		return nil, &UnknownOriginsError{Origins: unknownOrigins}
+	}
+
+	candidates := make(map[string]struct{})
+	for _, origin := range origins {
+		for destination := range s.latencyMatrix[origin.Region] {
+			candidates[destination] = struct{}{}
+		}
+		candidates[origin.Region] = struct{}{}
+	}
+
+	var eligibleRegions []Region
+	for destination := range candidates {
+		latency, qualifies := s.latencyAgainstOriginsLocked(origins, destination, mode)
+		if qualifies {
+			eligibleRegions = append(eligibleRegions, s.toRegionLocked(destination, latency))
+		}
+	}
+
+	return eligibleRegions, nil
+}
+
+// latencyAgainstOriginsLocked evaluates destination against every origin's
+// constraint for the given mode and must only be called while s.mu is held.
+func (s *LatencyService) latencyAgainstOriginsLocked(origins []OriginConstraint, destination, mode string) (latency float64, qualifies bool) {
+	if mode == BatchModeUnion {
+		for _, origin := range origins {
+			originLatency, ok := s.latencyToLocked(origin.Region, destination)
+			if ok && originLatency <= origin.MaxLatency && (!qualifies || originLatency < latency) {
+				latency = originLatency
+				qualifies = true
+			}
+		}
+		return latency, qualifies
+	}
+
+	// intersect (the default)
+	qualifies = true
+	for _, origin := range origins {
+		originLatency, ok := s.latencyToLocked(origin.Region, destination)
+		if !ok || originLatency > origin.MaxLatency {
+			return 0, false
+		}
+		if originLatency > latency {
+			latency = originLatency
+		}
+	}
+	return latency, qualifies
+}
+
+// latencyToLocked returns the latency from origin to destination, treating
+// a region's latency to itself as 0 when the matrix has no explicit entry
+// (mirroring FindEligibleRegions' origin-inclusion behavior).
+func (s *LatencyService) latencyToLocked(origin, destination string) (float64, bool) {
+	if latency, ok := s.latencyMatrix[origin][destination]; ok {
+		return latency, true
+	}
+	if origin == destination {
+		return 0, true
+	}
+	return 0, false
+}
+
+// FindEligibleRegionsPercentile filters regions by their p-th percentile
+// latency over the historical window [since, until] instead of the
+// current matrix. A destination with no samples in that window degrades
+// to its current-matrix latency; degraded reports true if that happened
+// for any destination (or there is no history at all for the origin), so
+// callers can surface a warning.
+func (s *LatencyService) FindEligibleRegionsPercentile(cloudProviderOriginRegion string, maxLatency, percentile float64, since, until *time.Time) (eligibleRegions []Region, degraded bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	currentLatencies, existsCurrent := s.latencyMatrix[cloudProviderOriginRegion]
+	originHistory, existsHistory := s.history[cloudProviderOriginRegion]
+	originSortedHistory := s.sortedHistory[cloudProviderOriginRegion]
+	if !existsCurrent && !existsHistory {
+		return nil, false, fmt.Errorf("cloudProviderOriginRegion %s not found", cloudProviderOriginRegion)
+	}
+
+	destinations := make(map[string]struct{})
+	for destination := range currentLatencies {
+		destinations[destination] = struct{}{}
+	}
+	for destination := range originHistory {
+		destinations[destination] = struct{}{}
+	}
+
+	// With no window, index directly into the pre-sorted samples computed
+	// once at load time instead of re-sorting per destination on every call.
+	unwindowed := since == nil && until == nil
+
+	for destination := range destinations {
+		var latency float64
+		var ok bool
+		if unwindowed {
+			latency, ok = percentileAtSorted(originSortedHistory[destination], percentile)
+		} else {
+			latency, ok = percentileAt(originHistory[destination], percentile, since, until)
+		}
+		if !ok {
+			latency, ok = currentLatencies[destination]
+			if !ok {
+				continue
+			}
+			degraded = true
+		}
+
+		if latency <= maxLatency {
+			eligibleRegions = append(eligibleRegions, s.toRegionLocked(destination, latency))
+		}
+	}
+
+	if _, exists := destinations[cloudProviderOriginRegion]; !exists {
+		eligibleRegions = append(eligibleRegions, s.toRegionLocked(cloudProviderOriginRegion, 0))
+	}
+
+	if !existsHistory {
+		degraded = true
+	}
+
+	return eligibleRegions, degraded, nil
+}
+
+// HistorySeries returns the raw chronological samples for a (source,
+// target) pair, for the /regions/history endpoint.
+func (s *LatencyService) HistorySeries(source, target string) ([]HistorySample, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	destinations, exists := s.history[source]
+	if !exists {
+		return nil, false
+	}
+	samples, exists := destinations[target]
+	return samples, exists
+}
+
+// toRegionLocked reads regionMappings and must only be called while s.mu is
+// held (for reading or writing) by the caller.
+func (s *LatencyService) toRegionLocked(region string, latencyMs float64) Region {
+	mapping, exists := s.regionMappings[region]
+	if !exists {
+		return Region{CloudProviderRegion: region, LatencyMs: latencyMs}
+	}
+
+	return Region{
+		CloudProviderRegion:   region,
+		ISOCountryCodeA2:      mapping.isoCode,
+		ElectricityMapsRegion: mapping.electricityMapsRegion,
+		PhysicalLocation:      mapping.physicalLocation,
+		AvailabilityZones:     mapping.availabilityZones,
+		Zones:                 mapping.zones,
+		LatencyMs:             latencyMs,
+	}
+}
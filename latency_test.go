@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLatencyServiceConcurrentReload reloads a LatencyService from disk
+// while other goroutines keep querying it, to prove FindEligibleRegions
+// never observes a torn (partially swapped) matrix or mapping under
+// concurrent load. Run with -race to catch data races.
+func TestLatencyServiceConcurrentReload(t *testing.T) {
+	service, err := NewLatencyService(CLOUD_AZURE, AZURE_LATENCY_MATRIX_FILE, AZURE_REGION_MAP_FILE, parseAzureMapping)
+	if err != nil {
+		t.Fatalf("NewLatencyService() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+
+	// Readers
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					if _, err := service.FindEligibleRegions("eastus", 1000); err != nil {
+						t.Errorf("FindEligibleRegions() error = %v", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	// Writer
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				if err := service.Reload(); err != nil {
+					t.Errorf("Reload() error = %v", err)
+					return
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,116 @@
+package main
+
+import "time"
+
+// SortBy values accepted by RegionRequest.SortBy.
+const (
+	SortByLatency = "latency"
+	SortByCarbon  = "carbon"
+	SortByPareto  = "pareto"
+)
+
+type RegionRequest struct {
+	CloudProvider             string  `json:"cloudProvider"`
+	CloudProviderOriginRegion string  `json:"cloudProviderOriginRegion"`
+	MaxLatency                float64 `json:"maxLatency"`
+	// MaxCarbonIntensity, in gCO2eq/kWh, drops regions whose current grid
+	// carbon intensity exceeds it. Zero means no carbon filtering.
+	MaxCarbonIntensity float64 `json:"maxCarbonIntensity,omitempty"`
+	// SortBy is one of SortByLatency (default), SortByCarbon or
+	// SortByPareto.
+	SortBy string `json:"sortBy,omitempty"`
+	// Percentile (e.g. 50, 95, 99), when set, switches eligibility from
+	// the current latency matrix to the p-th percentile latency observed
+	// across the loaded historical window. Since/Until optionally narrow
+	// that window.
+	Percentile float64    `json:"percentile,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	// AllowedCountries and DeniedCountries restrict eligible regions by data
+	// residency: each entry is either an ISO 3166-1 alpha-2 country code or a
+	// compliance group alias (e.g. "EEA") defined in data/compliance_groups.yaml.
+	// Leaving both empty applies no residency filtering.
+	AllowedCountries []string `json:"allowedCountries,omitempty"`
+	DeniedCountries  []string `json:"deniedCountries,omitempty"`
+}
+
+// WantsCarbonData reports whether the request needs carbon-intensity data
+// at all, so callers can skip the carbon cache lookup entirely otherwise.
+func (r RegionRequest) WantsCarbonData() bool {
+	return r.MaxCarbonIntensity > 0 || r.SortBy == SortByCarbon || r.SortBy == SortByPareto
+}
+
+// WantsPercentile reports whether the request asked for percentile-based,
+// history-window eligibility instead of current-matrix eligibility.
+func (r RegionRequest) WantsPercentile() bool {
+	return r.Percentile > 0
+}
+
+type Region struct {
+	CloudProviderRegion   string  `json:"cloudProviderRegion"`
+	ISOCountryCodeA2      string  `json:"isoCountryCodeA2"`
+	PhysicalLocation      string  `json:"physicalLocation"`
+	ElectricityMapsRegion string  `json:"electricityMapsRegion"`
+	AvailabilityZones     string  `json:"availabilityZones,omitempty"`
+	Zones                 string  `json:"zones,omitempty"`
+	LatencyMs             float64 `json:"latencyMs"`
+	// CarbonIntensity and CarbonFetchedAt are only set when the request
+	// asked for carbon data and a reading was available for this region's
+	// ElectricityMapsRegion.
+	CarbonIntensity *float64   `json:"carbonIntensity,omitempty"`
+	CarbonFetchedAt *time.Time `json:"carbonFetchedAt,omitempty"`
+}
+
+// RegionMapping holds the static, per-region metadata loaded from each
+// provider's mapping CSV. AvailabilityZones and Zones are only populated
+// for providers that expose that granularity (AWS and GCP respectively)
+// and are left empty otherwise.
+type RegionMapping struct {
+	isoCode               string
+	physicalLocation      string
+	electricityMapsRegion string
+	availabilityZones     string
+	zones                 string
+}
+
+type RegionResponse struct {
+	CloudProvider   string   `json:"cloudProvider"`
+	EligibleRegions []Region `json:"eligibleRegions"`
+	// CarbonDataAvailable is only present when the request asked for
+	// carbon data (see RegionRequest.WantsCarbonData). false means the
+	// carbon source was unavailable and the response fell back to
+	// latency-only filtering/sorting.
+	CarbonDataAvailable *bool `json:"carbonDataAvailable,omitempty"`
+}
+
+type ProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// AdminReloadRequest is the optional body for POST /admin/reload. An empty
+// CloudProvider reloads every provider.
+type AdminReloadRequest struct {
+	CloudProvider string `json:"cloudProvider"`
+}
+
+type AdminReloadResponse struct {
+	Reloaded []string `json:"reloaded"`
+}
+
+// HistorySample is one dated observation from a provider's historical
+// latency matrices (data/<provider>/history/YYYY-MM-DD.csv).
+type HistorySample struct {
+	Date      time.Time `json:"date"`
+	LatencyMs float64   `json:"latencyMs"`
+}
+
+type HistoryResponse struct {
+	CloudProvider string          `json:"cloudProvider"`
+	Source        string          `json:"source"`
+	Target        string          `json:"target"`
+	Samples       []HistorySample `json:"samples"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
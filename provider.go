@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	CLOUD_AZURE = "azure"
+	CLOUD_AWS   = "aws"
+	CLOUD_GCP   = "gcp"
+)
+
+const (
+	AZURE_LATENCY_MATRIX_FILE = "data/azure/azure_regions_latency_matrix.csv"
+	AZURE_REGION_MAP_FILE     = "data/azure/azure_region_mapping.csv"
+
+	AWS_LATENCY_MATRIX_FILE = "data/aws/aws_regions_latency_matrix.csv"
+	AWS_REGION_MAP_FILE     = "data/aws/aws_region_mapping.csv"
+
+	GCP_LATENCY_MATRIX_FILE = "data/gcp/gcp_regions_latency_matrix.csv"
+	GCP_REGION_MAP_FILE     = "data/gcp/gcp_region_mapping.csv"
+)
+
+// Provider is a cloud provider's region-latency backend. Each provider
+// owns its own latency matrix and region mapping, loaded from its own set
+// of data files, so providers can have different mapping schemas (e.g.
+// AWS availability zones, GCP zones) without affecting one another.
+type Provider interface {
+	Name() string
+	LoadMatrix(latencyFile string) error
+	LoadMappings(mappingFile string) error
+	FindEligibleRegions(cloudProviderOriginRegion string, maxLatency float64) ([]Region, error)
+
+	// Reload re-reads the latency matrix and region mappings from the files
+	// the provider was loaded from and swaps them in atomically.
+	Reload() error
+
+	// ElectricityMapsZones returns the distinct Electricity Maps zones
+	// referenced by this provider's region mappings, for the carbon cache
+	// to poll.
+	ElectricityMapsZones() []string
+
+	// FindEligibleRegionsPercentile is like FindEligibleRegions but
+	// filters on the p-th percentile latency over the historical window
+	// [since, until] instead of the current matrix.
+	FindEligibleRegionsPercentile(cloudProviderOriginRegion string, maxLatency, percentile float64, since, until *time.Time) (regions []Region, degraded bool, err error)
+
+	// HistorySeries returns the raw dated latency samples for a
+	// (source, target) pair.
+	HistorySeries(source, target string) ([]HistorySample, bool)
+
+	// FindEligibleRegionsMulti finds regions satisfying every origin's
+	// budget (BatchModeIntersect) or any origin's (BatchModeUnion).
+	FindEligibleRegionsMulti(origins []OriginConstraint, mode string) ([]Region, error)
+}
+
+// NewProviders loads every supported cloud provider's data and returns them
+// keyed by their CloudProvider identifier (the same value clients send as
+// RegionRequest.CloudProvider).
+func NewProviders() (map[string]Provider, error) {
+	providers := make(map[string]Provider)
+
+	azure, err := NewLatencyService(CLOUD_AZURE, AZURE_LATENCY_MATRIX_FILE, AZURE_REGION_MAP_FILE, parseAzureMapping)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing azure provider: %v", err)
+	}
+	providers[CLOUD_AZURE] = azure
+
+	aws, err := NewLatencyService(CLOUD_AWS, AWS_LATENCY_MATRIX_FILE, AWS_REGION_MAP_FILE, parseAWSMapping)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing aws provider: %v", err)
+	}
+	providers[CLOUD_AWS] = aws
+
+	gcp, err := NewLatencyService(CLOUD_GCP, GCP_LATENCY_MATRIX_FILE, GCP_REGION_MAP_FILE, parseGCPMapping)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing gcp provider: %v", err)
+	}
+	providers[CLOUD_GCP] = gcp
+
+	return providers, nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vicentinileonardo/region-filtering-server/internal/carbon"
+)
+
+type Server struct {
+	providers        map[string]Provider
+	carbonCache      *carbon.Cache
+	complianceGroups ComplianceGroups
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+}
+
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	json.NewEncoder(w).Encode(ProvidersResponse{Providers: names})
+}
+
+func (s *Server) handleEligibleRegions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request RegionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate input
+	if request.CloudProviderOriginRegion == "" {
+		writeJSONError(w, "cloud_provider_origin_region is required", http.StatusBadRequest)
+		return
+	}
+	if request.MaxLatency <= 0 {
+		writeJSONError(w, "max_latency must be greater than 0", http.StatusBadRequest)
+		return
+	}
+	if request.CloudProvider == "" {
+		writeJSONError(w, "cloud_provider is required", http.StatusBadRequest)
+		return
+	}
+
+	provider, exists := s.providers[request.CloudProvider]
+	if !exists {
+		writeJSONError(w, "unsupported cloud provider", http.StatusBadRequest)
+		return
+	}
+
+	allowedCountries, invalidAllowed := s.complianceGroups.Expand(request.AllowedCountries)
+	deniedCountries, invalidDenied := s.complianceGroups.Expand(request.DeniedCountries)
+	if invalid := append(invalidAllowed, invalidDenied...); len(invalid) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(InvalidCountryCodesErrorResponse{
+			Error:               "unknown country codes or compliance groups",
+			InvalidCountryCodes: invalid,
+		})
+		return
+	}
+
+	// Find eligible regions, either against the current matrix or, when a
+	// percentile was requested, against the historical window.
+	var eligibleRegions []Region
+	var degraded bool
+	var err error
+	if request.WantsPercentile() {
+		eligibleRegions, degraded, err = provider.FindEligibleRegionsPercentile(
+			request.CloudProviderOriginRegion, request.MaxLatency, request.Percentile, request.Since, request.Until)
+	} else {
+		eligibleRegions, err = provider.FindEligibleRegions(request.CloudProviderOriginRegion, request.MaxLatency)
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if degraded {
+		w.Header().Set("Warning", `199 - "insufficient historical samples for the requested window, degraded to current latency matrix"`)
+	}
+
+	eligibleRegions = filterByResidency(eligibleRegions, allowedCountries, deniedCountries)
+
+	eligibleRegions, carbonDataAvailable := applyCarbonAwareness(eligibleRegions, s.carbonCache, request)
+
+	// log eligible regions
+	log.Printf("Eligible regions for %s with max latency %f: %v", request.CloudProviderOriginRegion, request.MaxLatency, eligibleRegions)
+
+	json.NewEncoder(w).Encode(RegionResponse{
+		CloudProvider:       request.CloudProvider,
+		EligibleRegions:     eligibleRegions,
+		CarbonDataAvailable: carbonDataAvailable,
+	})
+}
+
+func (s *Server) handleRegionsHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	cloudProvider := query.Get("cloudProvider")
+	source := query.Get("source")
+	target := query.Get("target")
+
+	if source == "" || target == "" {
+		writeJSONError(w, "source and target are required", http.StatusBadRequest)
+		return
+	}
+	if cloudProvider == "" {
+		writeJSONError(w, "cloudProvider is required", http.StatusBadRequest)
+		return
+	}
+
+	provider, exists := s.providers[cloudProvider]
+	if !exists {
+		writeJSONError(w, "unsupported cloud provider", http.StatusBadRequest)
+		return
+	}
+
+	samples, exists := provider.HistorySeries(source, target)
+	if !exists {
+		writeJSONError(w, fmt.Sprintf("no history for %s -> %s", source, target), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(HistoryResponse{
+		CloudProvider: cloudProvider,
+		Source:        source,
+		Target:        target,
+		Samples:       samples,
+	})
+}
+
+func (s *Server) handleEligibleRegionsBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request BatchRegionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.CloudProvider == "" {
+		writeJSONError(w, "cloud_provider is required", http.StatusBadRequest)
+		return
+	}
+	if len(request.Origins) == 0 {
+		writeJSONError(w, "origins must contain at least one entry", http.StatusBadRequest)
+		return
+	}
+	for _, origin := range request.Origins {
+		if origin.Region == "" {
+			writeJSONError(w, "every origin must have a region", http.StatusBadRequest)
+			return
+		}
+		if origin.MaxLatency <= 0 {
+			writeJSONError(w, "every origin's max_latency must be greater than 0", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Mode != BatchModeIntersect && request.Mode != BatchModeUnion {
+		writeJSONError(w, "mode must be \"intersect\" or \"union\"", http.StatusBadRequest)
+		return
+	}
+
+	provider, exists := s.providers[request.CloudProvider]
+	if !exists {
+		writeJSONError(w, "unsupported cloud provider", http.StatusBadRequest)
+		return
+	}
+
+	eligibleRegions, err := provider.FindEligibleRegionsMulti(request.Origins, request.Mode)
+	if err != nil {
+		var unknownOrigins *UnknownOriginsError
+		if errors.As(err, &unknownOrigins) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(UnknownOriginsErrorResponse{
+				Error:          err.Error(),
+				UnknownOrigins: unknownOrigins.Origins,
+			})
+			return
+		}
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(BatchRegionResponse{
+		CloudProvider:   request.CloudProvider,
+		Mode:            request.Mode,
+		EligibleRegions: eligibleRegions,
+	})
+}
+
+func (s *Server) handleAdminReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request AdminReloadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	targets := s.providers
+	if request.CloudProvider != "" {
+		provider, exists := s.providers[request.CloudProvider]
+		if !exists {
+			writeJSONError(w, "unsupported cloud provider", http.StatusBadRequest)
+			return
+		}
+		targets = map[string]Provider{request.CloudProvider: provider}
+	}
+
+	reloaded := make([]string, 0, len(targets))
+	for name, provider := range targets {
+		if err := provider.Reload(); err != nil {
+			writeJSONError(w, fmt.Sprintf("error reloading %s: %v", name, err), http.StatusInternalServerError)
+			return
+		}
+		reloaded = append(reloaded, name)
+	}
+	sort.Strings(reloaded)
+
+	json.NewEncoder(w).Encode(AdminReloadResponse{Reloaded: reloaded})
+}
+
+func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+}
+
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		log.Printf("Started %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+		log.Printf("Completed %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+	})
+}
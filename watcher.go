@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher reloads a provider's latency matrix and region mappings whenever
+// the CSV files in its data directory change on disk, so operators no
+// longer need to restart the server to pick up new data.
+type Watcher struct {
+	fsWatcher    *fsnotify.Watcher
+	dirProviders map[string]Provider
+}
+
+// NewWatcher starts watching each provider's data directory (providerDirs
+// maps a directory to the provider whose files live in it) and reloads
+// that provider whenever one of its files is written.
+func NewWatcher(providerDirs map[string]Provider) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating fsnotify watcher: %v", err)
+	}
+
+	dirProviders := make(map[string]Provider, len(providerDirs))
+	for dir, provider := range providerDirs {
+		if err := fsWatcher.Add(dir); err != nil {
+			fsWatcher.Close()
+			return nil, fmt.Errorf("error watching %s: %v", dir, err)
+		}
+		dirProviders[filepath.Clean(dir)] = provider
+
+		// fsnotify doesn't watch subdirectories, so the history/ directory
+		// (dated CSVs added by later requests) needs its own watch to pick
+		// up new files. It's optional, so a missing history dir is fine.
+		historyDir := filepath.Join(dir, "history")
+		if _, err := os.Stat(historyDir); err != nil {
+			continue
+		}
+		if err := fsWatcher.Add(historyDir); err != nil {
+			fsWatcher.Close()
+			return nil, fmt.Errorf("error watching %s: %v", historyDir, err)
+		}
+		dirProviders[filepath.Clean(historyDir)] = provider
+	}
+
+	w := &Watcher{fsWatcher: fsWatcher, dirProviders: dirProviders}
+	go w.run()
+	return w, nil
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			w.reload(filepath.Dir(event.Name))
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Watcher error: %v", err)
+		}
+	}
+}
+
+func (w *Watcher) reload(dir string) {
+	provider, exists := w.dirProviders[filepath.Clean(dir)]
+	if !exists {
+		return
+	}
+
+	if err := provider.Reload(); err != nil {
+		log.Printf("Failed to reload %s provider data: %v", provider.Name(), err)
+		return
+	}
+
+	log.Printf("Reloaded %s provider data from %s", provider.Name(), dir)
+}
+
+func (w *Watcher) Close() error {
+	return w.fsWatcher.Close()
+}